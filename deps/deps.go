@@ -0,0 +1,370 @@
+// Package deps manages Predator's bundled ffmpeg/ffprobe: fetching static
+// builds from the BtbN/FFmpeg-Builds GitHub releases, verifying them
+// against published checksums, and extracting each version into its own
+// directory under the app's storage directory. Every InstallPinned call
+// downloads the release afresh; an already-extracted version is not reused.
+package deps
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const releasesAPI = "https://api.github.com/repos/BtbN/FFmpeg-Builds/releases/latest"
+
+// Asset is one downloadable file attached to a GitHub release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Release is the subset of the GitHub releases API response we need.
+type Release struct {
+	TagName     string  `json:"tag_name"`
+	PublishedAt string  `json:"published_at"`
+	Assets      []Asset `json:"assets"`
+}
+
+// Version returns a stable identifier for what this release actually
+// contains. BtbN republishes new binaries under the same rolling "latest"
+// tag, so TagName alone can't detect an update; PublishedAt changes every
+// time the release is republished.
+func (r *Release) Version() string {
+	return r.TagName + "-" + r.PublishedAt
+}
+
+// ProgressFunc is called periodically during Download with bytes
+// downloaded so far and the expected total: Content-Length, else the
+// asset's listed size, else 0.
+type ProgressFunc func(downloaded, total int64)
+
+// assetSuffix returns the BtbN asset-name suffix for the running platform,
+// or "" when BtbN publishes no build for it (notably darwin).
+func assetSuffix() string {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "windows/amd64":
+		return "win64-gpl.zip"
+	case "linux/amd64":
+		return "linux64-gpl.tar.xz"
+	case "linux/arm64":
+		return "linuxarm64-gpl.tar.xz"
+	default:
+		return ""
+	}
+}
+
+// Supported reports whether BtbN publishes a build for the running
+// platform. darwin/amd64 and darwin/arm64 are not published by BtbN, so
+// callers should fall back to yt-dlp's own bundled installer there — see
+// installDeps, which checks Supported() before calling InstallPinned and
+// falls back to ytdlp.Install otherwise.
+func Supported() bool {
+	return assetSuffix() != ""
+}
+
+// FetchLatestRelease queries the GitHub API for the latest FFmpeg-Builds
+// release.
+func FetchLatestRelease(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releasesAPI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build release request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query github releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github releases returned %s", resp.Status)
+	}
+
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("decode github release: %w", err)
+	}
+	return &rel, nil
+}
+
+// SelectAsset picks the archive and its .sha256 sidecar matching the
+// running platform out of rel.Assets. A release that publishes the
+// archive without a matching sidecar is treated as an error rather than
+// an unverified install, since checksum verification is not optional.
+func SelectAsset(rel *Release) (archive, checksum *Asset, err error) {
+	suffix := assetSuffix()
+	if suffix == "" {
+		return nil, nil, fmt.Errorf("no BtbN build published for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	for i := range rel.Assets {
+		a := &rel.Assets[i]
+		if !strings.HasSuffix(a.Name, suffix) {
+			continue
+		}
+		if strings.HasSuffix(a.Name, ".sha256") {
+			continue
+		}
+		archive = a
+		break
+	}
+	if archive == nil {
+		return nil, nil, fmt.Errorf("no asset matching %q in release %s", suffix, rel.TagName)
+	}
+	for i := range rel.Assets {
+		a := &rel.Assets[i]
+		if a.Name == archive.Name+".sha256" {
+			checksum = a
+			break
+		}
+	}
+	if checksum == nil {
+		return nil, nil, fmt.Errorf("no .sha256 sidecar for %q in release %s", archive.Name, rel.TagName)
+	}
+	return archive, checksum, nil
+}
+
+// Download streams asset to destDir, reporting progress via onProgress,
+// and returns the downloaded file's path.
+func Download(ctx context.Context, asset *Asset, destDir string, onProgress ProgressFunc) (string, error) {
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return "", fmt.Errorf("create download dir: %w", err)
+	}
+	dest := filepath.Join(destDir, asset.Name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("build download request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s returned %s", asset.Name, resp.Status)
+	}
+
+	total := resp.ContentLength
+	if total <= 0 {
+		total = asset.Size
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return "", fmt.Errorf("create %s: %w", dest, err)
+	}
+	defer f.Close()
+
+	var downloaded int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, rerr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := f.Write(buf[:n]); werr != nil {
+				return "", fmt.Errorf("write %s: %w", dest, werr)
+			}
+			downloaded += int64(n)
+			if onProgress != nil {
+				onProgress(downloaded, total)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return "", fmt.Errorf("download %s: %w", asset.Name, rerr)
+		}
+	}
+
+	return dest, nil
+}
+
+// fetchChecksum downloads a .sha256 sidecar and returns the lowercase hex
+// digest it contains.
+func fetchChecksum(ctx context.Context, checksum *Asset) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksum.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("build checksum request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download checksum: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read checksum: %w", err)
+	}
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file")
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// VerifySHA256 downloads the checksum sidecar for checksum and confirms it
+// matches the SHA-256 of the file at path.
+func VerifySHA256(ctx context.Context, checksum *Asset, path string) error {
+	want, err := fetchChecksum(ctx, checksum)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("hash %s: %w", path, err)
+	}
+	got := hex.EncodeToString(h.Sum(nil))
+
+	if got != want {
+		return fmt.Errorf("checksum mismatch for %s: want %s, got %s", filepath.Base(path), want, got)
+	}
+	return nil
+}
+
+// Extract unpacks archivePath (a .zip or .tar.xz) into destDir and returns
+// the directory containing the ffmpeg/ffprobe binaries.
+func Extract(archivePath, destDir string) (string, error) {
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return "", fmt.Errorf("create extract dir: %w", err)
+	}
+
+	switch {
+	case strings.HasSuffix(archivePath, ".zip"):
+		if err := extractZip(archivePath, destDir); err != nil {
+			return "", err
+		}
+	case strings.HasSuffix(archivePath, ".tar.xz"):
+		// The stdlib has no xz decompressor; BtbN builds always ship with
+		// a system tar capable of handling .tar.xz on linux.
+		cmd := exec.Command("tar", "-xJf", archivePath, "-C", destDir)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("extract %s: %w: %s", archivePath, err, out)
+		}
+	default:
+		return "", fmt.Errorf("unsupported archive format: %s", archivePath)
+	}
+
+	return binDir(destDir)
+}
+
+func extractZip(archivePath, destDir string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("open zip %s: %w", archivePath, err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		target := filepath.Join(destDir, f.Name)
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("open %s in zip: %w", f.Name, err)
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode())
+		if err != nil {
+			rc.Close()
+			return fmt.Errorf("create %s: %w", target, err)
+		}
+		_, err = io.Copy(out, rc)
+		rc.Close()
+		out.Close()
+		if err != nil {
+			return fmt.Errorf("write %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// sanitizeDirName strips characters that are invalid in a path component on
+// common filesystems (PublishedAt is an RFC3339 timestamp and contains ':').
+func sanitizeDirName(s string) string {
+	return strings.NewReplacer(":", "-", "/", "-").Replace(s)
+}
+
+// binDir walks the extracted archive and returns the directory holding
+// ffmpeg/ffmpeg.exe — BtbN archives nest the binaries under
+// "<name>/bin/".
+func binDir(root string) (string, error) {
+	binName := "ffmpeg"
+	if runtime.GOOS == "windows" {
+		binName = "ffmpeg.exe"
+	}
+
+	var found string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found != "" {
+			return err
+		}
+		if !info.IsDir() && info.Name() == binName {
+			found = filepath.Dir(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("scan extracted archive: %w", err)
+	}
+	if found == "" {
+		return "", fmt.Errorf("ffmpeg binary not found under %s", root)
+	}
+	return found, nil
+}
+
+// InstallPinned fetches, verifies and extracts the latest BtbN build into
+// rootDir/bin/ffmpeg-<version>/, returning the directory to prepend to
+// PATH and the release's Version(). Callers should fall back to yt-dlp's
+// bundled installer if Supported() is false or this returns an error.
+func InstallPinned(ctx context.Context, rootDir string, onProgress ProgressFunc) (binDir, version string, err error) {
+	rel, err := FetchLatestRelease(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	archive, checksum, err := SelectAsset(rel)
+	if err != nil {
+		return "", "", err
+	}
+
+	tmpDir := filepath.Join(rootDir, "downloads")
+	archivePath, err := Download(ctx, archive, tmpDir, onProgress)
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(archivePath)
+
+	if err := VerifySHA256(ctx, checksum, archivePath); err != nil {
+		return "", "", err
+	}
+
+	versionDir := filepath.Join(rootDir, "bin", "ffmpeg-"+sanitizeDirName(rel.Version()))
+	binDir, err = Extract(archivePath, versionDir)
+	return binDir, rel.Version(), err
+}
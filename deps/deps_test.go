@@ -0,0 +1,61 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSanitizeDirName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2024-05-01T12:00:00Z", "2024-05-01T12-00-00Z"},
+		{"foo/bar", "foo-bar"},
+		{"plain", "plain"},
+	}
+	for _, tc := range tests {
+		if got := sanitizeDirName(tc.in); got != tc.want {
+			t.Errorf("sanitizeDirName(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestBinDir(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "ffmpeg-master-latest-linux64-gpl", "bin")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, "ffmpeg"), []byte("stub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := binDir(root)
+	if err != nil {
+		t.Fatalf("binDir() error = %v", err)
+	}
+	if got != nested {
+		t.Errorf("binDir() = %q, want %q", got, nested)
+	}
+}
+
+func TestBinDirNotFound(t *testing.T) {
+	root := t.TempDir()
+	if _, err := binDir(root); err == nil {
+		t.Fatal("binDir() error = nil, want error for archive with no ffmpeg binary")
+	}
+}
+
+func TestSelectAssetRequiresChecksumSidecar(t *testing.T) {
+	rel := &Release{
+		TagName: "latest",
+		Assets: []Asset{
+			{Name: "ffmpeg-master-latest-linux64-gpl.tar.xz"},
+		},
+	}
+
+	if _, _, err := SelectAsset(rel); err == nil {
+		t.Fatal("SelectAsset() error = nil, want error when no .sha256 sidecar is published")
+	}
+}
@@ -0,0 +1,133 @@
+// Package formats builds yt-dlp format-selector strings from user-facing
+// quality caps and codec preferences, so main.go no longer hard-codes a
+// single format string.
+package formats
+
+import "fmt"
+
+// FormatOptions carries the UI's current quality caps and preferences into
+// a Selector.
+type FormatOptions struct {
+	MaxHeight      int   // 0 means no cap
+	Prefer60fps    bool
+	PreferHDR      bool
+	MaxFilesizeMiB int64 // 0 means no cap
+}
+
+// Selector builds a yt-dlp -f string for one codec/container strategy.
+type Selector interface {
+	// Name is shown in the container/codec dropdown.
+	Name() string
+	// BuildFormatString returns the format selector to pass to
+	// ytdlp.New().Format(...) for the given options.
+	BuildFormatString(opts FormatOptions) string
+}
+
+// heightClause returns a "[height<=N]" clause, or "" when uncapped.
+func heightClause(opts FormatOptions) string {
+	if opts.MaxHeight <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("[height<=%d]", opts.MaxHeight)
+}
+
+// fpsClause returns a "[fps>=50]" clause when 60fps is preferred, steering
+// the selector toward high-framerate variants without excluding 30fps-only
+// uploads entirely (that's left to the fallback branch).
+func fpsClause(opts FormatOptions) string {
+	if !opts.Prefer60fps {
+		return ""
+	}
+	return "[fps>=50]"
+}
+
+// hdrClause returns a "[dynamic_range*=HDR]" clause when HDR is preferred,
+// steering the selector toward HDR variants without excluding SDR-only
+// uploads entirely (that's left to the fallback branch).
+func hdrClause(opts FormatOptions) string {
+	if !opts.PreferHDR {
+		return ""
+	}
+	return "[dynamic_range*=HDR]"
+}
+
+// filesizeClause returns a "[filesize<=NMiB]" clause, or "" when uncapped.
+// yt-dlp's filesize filter follows parse_filesize, where a bare "M" suffix
+// is decimal (10^6 bytes) and "MiB" is binary (2^20 bytes); MaxFilesizeMiB
+// is binary, so the clause must say so explicitly or the cap comes out
+// about 5% smaller than what the user typed.
+func filesizeClause(opts FormatOptions) string {
+	if opts.MaxFilesizeMiB <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("[filesize<=%dMiB]", opts.MaxFilesizeMiB)
+}
+
+// MP4H264Selector favors H.264/AAC in an MP4 container for maximum player
+// compatibility.
+type MP4H264Selector struct{}
+
+func (MP4H264Selector) Name() string { return "MP4 (H.264)" }
+
+func (MP4H264Selector) BuildFormatString(opts FormatOptions) string {
+	h, f, hd, sz := heightClause(opts), fpsClause(opts), hdrClause(opts), filesizeClause(opts)
+	return fmt.Sprintf(
+		"bestvideo[ext=mp4][vcodec^=avc1]%s%s%s%s+bestaudio[ext=m4a]/"+
+			"bestvideo[ext=mp4]%s%s+bestaudio/best%s",
+		h, f, hd, sz, h, sz, h,
+	)
+}
+
+// AV1Selector prefers the AV1 codec, which offers better compression at
+// the cost of player/hardware-decode support.
+type AV1Selector struct{}
+
+func (AV1Selector) Name() string { return "AV1" }
+
+func (AV1Selector) BuildFormatString(opts FormatOptions) string {
+	h, f, hd, sz := heightClause(opts), fpsClause(opts), hdrClause(opts), filesizeClause(opts)
+	return fmt.Sprintf(
+		"bestvideo[vcodec^=av01]%s%s%s%s+bestaudio/bestvideo%s%s+bestaudio/best%s",
+		h, f, hd, sz, h, sz, h,
+	)
+}
+
+// VP9WebMSelector prefers VP9 video in a WebM container.
+type VP9WebMSelector struct{}
+
+func (VP9WebMSelector) Name() string { return "WebM (VP9)" }
+
+func (VP9WebMSelector) BuildFormatString(opts FormatOptions) string {
+	h, f, hd, sz := heightClause(opts), fpsClause(opts), hdrClause(opts), filesizeClause(opts)
+	return fmt.Sprintf(
+		"bestvideo[ext=webm][vcodec^=vp9]%s%s%s%s+bestaudio[ext=webm]/"+
+			"bestvideo[vcodec^=vp9]%s%s+bestaudio/best%s",
+		h, f, hd, sz, h, sz, h,
+	)
+}
+
+// AtmosPreferSelector prefers a Dolby Atmos / E-AC-3 audio track alongside
+// the best available video, falling back to plain best-audio when the
+// source has no surround track.
+type AtmosPreferSelector struct{}
+
+func (AtmosPreferSelector) Name() string { return "Atmos audio preferred" }
+
+func (AtmosPreferSelector) BuildFormatString(opts FormatOptions) string {
+	h, f, hd, sz := heightClause(opts), fpsClause(opts), hdrClause(opts), filesizeClause(opts)
+	return fmt.Sprintf(
+		"bestvideo%s%s%s%s+bestaudio[acodec^=ec-3]/bestvideo%s%s+bestaudio/best%s",
+		h, f, hd, sz, h, sz, h,
+	)
+}
+
+// Selectors lists every built-in Selector in the order they should appear
+// in the container dropdown.
+func Selectors() []Selector {
+	return []Selector{
+		MP4H264Selector{},
+		AV1Selector{},
+		VP9WebMSelector{},
+		AtmosPreferSelector{},
+	}
+}
@@ -0,0 +1,74 @@
+package formats
+
+import "testing"
+
+func TestBuildFormatString(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector Selector
+		opts     FormatOptions
+		want     string
+	}{
+		{
+			name:     "mp4 uncapped",
+			selector: MP4H264Selector{},
+			opts:     FormatOptions{},
+			want:     "bestvideo[ext=mp4][vcodec^=avc1]+bestaudio[ext=m4a]/bestvideo[ext=mp4]+bestaudio/best",
+		},
+		{
+			name:     "mp4 height capped",
+			selector: MP4H264Selector{},
+			opts:     FormatOptions{MaxHeight: 720},
+			want:     "bestvideo[ext=mp4][vcodec^=avc1][height<=720]+bestaudio[ext=m4a]/bestvideo[ext=mp4][height<=720]+bestaudio/best[height<=720]",
+		},
+		{
+			name:     "av1 with fps and filesize",
+			selector: AV1Selector{},
+			opts:     FormatOptions{Prefer60fps: true, MaxFilesizeMiB: 500},
+			want:     "bestvideo[vcodec^=av01][fps>=50][filesize<=500MiB]+bestaudio/bestvideo[filesize<=500MiB]+bestaudio/best",
+		},
+		{
+			name:     "vp9 webm uncapped",
+			selector: VP9WebMSelector{},
+			opts:     FormatOptions{},
+			want:     "bestvideo[ext=webm][vcodec^=vp9]+bestaudio[ext=webm]/bestvideo[vcodec^=vp9]+bestaudio/best",
+		},
+		{
+			name:     "mp4 hdr preferred",
+			selector: MP4H264Selector{},
+			opts:     FormatOptions{PreferHDR: true},
+			want:     "bestvideo[ext=mp4][vcodec^=avc1][dynamic_range*=HDR]+bestaudio[ext=m4a]/bestvideo[ext=mp4]+bestaudio/best",
+		},
+		{
+			name:     "atmos prefer with height cap",
+			selector: AtmosPreferSelector{},
+			opts:     FormatOptions{MaxHeight: 1080},
+			want:     "bestvideo[height<=1080]+bestaudio[acodec^=ec-3]/bestvideo[height<=1080]+bestaudio/best[height<=1080]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.selector.BuildFormatString(tc.opts)
+			if got != tc.want {
+				t.Errorf("%s.BuildFormatString() = %q, want %q", tc.selector.Name(), got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectorsOrder(t *testing.T) {
+	names := make([]string, 0, 4)
+	for _, s := range Selectors() {
+		names = append(names, s.Name())
+	}
+	want := []string{"MP4 (H.264)", "AV1", "WebM (VP9)", "Atmos audio preferred"}
+	if len(names) != len(want) {
+		t.Fatalf("Selectors() returned %d selectors, want %d", len(names), len(want))
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Selectors()[%d].Name() = %q, want %q", i, names[i], want[i])
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -17,9 +19,14 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/lrstanley/go-ytdlp"
+
+	"github.com/Aswanidev-vs/Predator/deps"
+	"github.com/Aswanidev-vs/Predator/formats"
+	"github.com/Aswanidev-vs/Predator/postprocess"
+	"github.com/Aswanidev-vs/Predator/queue"
 )
 
-func checkAndInstallDeps(w fyne.Window) error {
+func checkAndInstallDeps(a fyne.App, w fyne.Window) error {
 
 	// Quick check: do we have system ffmpeg and ffprobe in PATH?
 	if _, err := exec.LookPath("ffmpeg"); err == nil {
@@ -36,52 +43,287 @@ func checkAndInstallDeps(w fyne.Window) error {
 		"Install Required Tools",
 		"Predator requires ffmpeg and ffprobe for merging video+audio and extracting audio.\n\n"+
 			"They are not detected on your system.\n\n"+
-			"We can automatically download open-source bundled versions (yt-dlp + ffmpeg + ffprobe) and cache them locally.\n\n"+
+			"We can automatically download a pinned, checksum-verified ffmpeg/ffprobe build and cache it locally.\n\n"+
 			"Do you want to continue? (Recommended)",
 		func(ok bool) {
 			if !ok {
 				done <- fmt.Errorf("user declined bundled dependency installation")
 				return
 			}
+			go func() { done <- installDeps(a, w) }()
+		},
+		w,
+	)
 
-			// Show progress
-			bar := widget.NewProgressBarInfinite()
-			label := widget.NewLabel("Downloading yt-dlp, ffmpeg & ffprobe…\nThis may take a moment on first run.")
-			content := container.NewVBox(label, bar)
+	confirm.SetDismissText("No")
+	confirm.SetConfirmText("Yes, Install")
+	confirm.Show()
 
-			progressDialog := dialog.NewCustomWithoutButtons("Installing Dependencies", content, w)
-			progressDialog.Show()
-			go func() {
-				defer progressDialog.Hide()
+	return <-done
+}
 
-				defer func() {
-					if r := recover(); r != nil {
-						done <- fmt.Errorf("installation panicked: %v", r)
-					}
-				}()
+// installDeps downloads a pinned ffmpeg/ffprobe build from the
+// BtbN/FFmpeg-Builds releases via the deps package, showing determinate
+// download progress, and prepends the extracted bin directory to PATH for
+// subsequent ytdlp.New() invocations. If BtbN has no build for this
+// platform, or the GitHub API is unreachable, it falls back to yt-dlp's
+// own bundled installer.
+func installDeps(a fyne.App, w fyne.Window) error {
+	bar := widget.NewProgressBar()
+	label := widget.NewLabel("Downloading ffmpeg & ffprobe…\nThis may take a moment on first run.")
+	content := container.NewVBox(label, bar)
+
+	progressDialog := dialog.NewCustomWithoutButtons("Installing Dependencies", content, w)
+	fyne.Do(progressDialog.Show)
+	defer fyne.Do(progressDialog.Hide)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("installDeps panicked:", r)
+		}
+	}()
+
+	if deps.Supported() {
+		root := a.Storage().RootURI().Path()
+		binDir, version, err := deps.InstallPinned(context.Background(), root, func(downloaded, total int64) {
+			fyne.Do(func() {
+				if total > 0 {
+					bar.SetValue(float64(downloaded) / float64(total))
+				}
+			})
+		})
+		if err == nil {
+			if err := os.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH")); err != nil {
+				return fmt.Errorf("update PATH: %w", err)
+			}
+			a.Preferences().SetString(prefFFmpegVersion, version)
+			ytdlp.MustInstall(context.Background(), nil)
+			return nil
+		}
+		log.Println("pinned ffmpeg install failed, falling back to yt-dlp's bundled installer:", err)
+	}
+
+	fyne.Do(func() { bar.SetValue(0) })
+	if _, err := ytdlp.Install(context.Background(), nil); err != nil {
+		return fmt.Errorf("failed to install dependencies: %w", err)
+	}
+	return nil
+}
+
+// applyNetworkOptions wires the persisted cookies/proxy/rate-limit
+// settings onto builder. It is applied to every ytdlp.New() builder in
+// both fetchVideoInfo and downloadBtn.OnTapped so restricted or throttled
+// content behaves the same whether we're just probing info or actually
+// downloading.
+func applyNetworkOptions(builder *ytdlp.Command, prefs fyne.Preferences) *ytdlp.Command {
+	if path := prefs.String(prefCookiesPath); path != "" {
+		builder = builder.Cookies(path)
+	}
+	if browser := prefs.String(prefCookiesBrowser); browser != "" {
+		builder = builder.CookiesFromBrowser(browser)
+	}
+	if proxy := prefs.String(prefProxy); proxy != "" {
+		builder = builder.Proxy(proxy)
+	}
+	if rate := prefs.String(prefLimitRate); rate != "" {
+		builder = builder.LimitRate(rate)
+	}
+	if retries := prefs.IntWithFallback(prefRetries, 10); retries > 0 {
+		builder = builder.Retries(fmt.Sprintf("%d", retries))
+	}
+	if fr := prefs.IntWithFallback(prefFragmentRetries, 10); fr > 0 {
+		builder = builder.FragmentRetries(fmt.Sprintf("%d", fr))
+	}
+	if cf := prefs.Int(prefConcurrentFragments); cf > 0 {
+		builder = builder.ConcurrentFragments(cf)
+	}
+	if ua := prefs.String(prefUserAgent); ua != "" {
+		builder = builder.UserAgent(ua)
+	}
+	return builder
+}
 
-				// Fixed: handle two return values
-				_, err := ytdlp.Install(context.Background(), nil)
+// showSettingsDialog lets the user check for and install a newer pinned
+// ffmpeg/ffprobe build, and configure cookies/proxy/rate-limit settings
+// used for restricted or throttled content, with a connection test against
+// urlEntry's current URL.
+func showSettingsDialog(a fyne.App, w fyne.Window, urlEntry *widget.Entry) {
+	prefs := a.Preferences()
+
+	versionLabel := widget.NewLabel("Installed ffmpeg: " + prefs.StringWithFallback(prefFFmpegVersion, "system / unknown"))
+
+	checkBtn := widget.NewButton("Check for updates", func() {
+		go func() {
+			rel, err := deps.FetchLatestRelease(context.Background())
+			fyne.Do(func() {
 				if err != nil {
-					done <- fmt.Errorf("failed to install dependencies: %w", err)
+					dialog.ShowError(fmt.Errorf("check for updates: %w", err), w)
 					return
 				}
+				current := prefs.StringWithFallback(prefFFmpegVersion, "")
+				if current == rel.Version() {
+					dialog.ShowInformation("Up to date", "ffmpeg "+rel.TagName+" is already installed.", w)
+					return
+				}
+				dialog.ShowConfirm(
+					"Update available",
+					fmt.Sprintf("ffmpeg %s (published %s) is available (currently %q). Download now?", rel.TagName, rel.PublishedAt, current),
+					func(ok bool) {
+						if !ok {
+							return
+						}
+						go func() {
+							if err := installDeps(a, w); err != nil {
+								fyne.Do(func() { dialog.ShowError(err, w) })
+							} else {
+								fyne.Do(func() {
+									versionLabel.SetText("Installed ffmpeg: " + prefs.StringWithFallback(prefFFmpegVersion, "system / unknown"))
+								})
+							}
+						}()
+					},
+					w,
+				)
+			})
+		}()
+	})
+
+	cookiesPathEntry := widget.NewEntry()
+	cookiesPathEntry.SetText(prefs.String(prefCookiesPath))
+	cookiesPathEntry.SetPlaceHolder("Path to cookies.txt (Netscape format)")
+	browseCookiesBtn := widget.NewButton("Browse…", func() {
+		dialog.NewFileOpen(func(uri fyne.URIReadCloser, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			defer uri.Close()
+			cookiesPathEntry.SetText(uri.URI().Path())
+		}, w).Show()
+	})
 
-				done <- nil
-			}()
+	cookiesBrowserSelect := widget.NewSelect([]string{"", "firefox", "chrome", "edge", "safari"}, nil)
+	cookiesBrowserSelect.SetSelected(prefs.String(prefCookiesBrowser))
 
-		},
-		w,
-	)
+	proxyEntry := widget.NewEntry()
+	proxyEntry.SetText(prefs.String(prefProxy))
+	proxyEntry.SetPlaceHolder("http://, https:// or socks5:// proxy URL")
 
-	confirm.SetDismissText("No")
-	confirm.SetConfirmText("Yes, Install")
-	confirm.Show()
+	limitRateEntry := widget.NewEntry()
+	limitRateEntry.SetText(prefs.String(prefLimitRate))
+	limitRateEntry.SetPlaceHolder("Download rate limit, e.g. 2M")
 
-	return <-done
+	retriesEntry := widget.NewEntry()
+	retriesEntry.SetText(fmt.Sprintf("%d", prefs.IntWithFallback(prefRetries, 10)))
+
+	fragmentRetriesEntry := widget.NewEntry()
+	fragmentRetriesEntry.SetText(fmt.Sprintf("%d", prefs.IntWithFallback(prefFragmentRetries, 10)))
+
+	concurrentFragmentsEntry := widget.NewEntry()
+	concurrentFragmentsEntry.SetText(fmt.Sprintf("%d", prefs.IntWithFallback(prefConcurrentFragments, 1)))
+
+	userAgentEntry := widget.NewEntry()
+	userAgentEntry.SetText(prefs.String(prefUserAgent))
+	userAgentEntry.SetPlaceHolder("Custom User-Agent (blank = yt-dlp default)")
+
+	saveNetworkSettings := func() {
+		prefs.SetString(prefCookiesPath, strings.TrimSpace(cookiesPathEntry.Text))
+		prefs.SetString(prefCookiesBrowser, cookiesBrowserSelect.Selected)
+		prefs.SetString(prefProxy, strings.TrimSpace(proxyEntry.Text))
+		prefs.SetString(prefLimitRate, strings.TrimSpace(limitRateEntry.Text))
+		if n, err := strconv.Atoi(strings.TrimSpace(retriesEntry.Text)); err == nil {
+			prefs.SetInt(prefRetries, n)
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(fragmentRetriesEntry.Text)); err == nil {
+			prefs.SetInt(prefFragmentRetries, n)
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(concurrentFragmentsEntry.Text)); err == nil {
+			prefs.SetInt(prefConcurrentFragments, n)
+		}
+		prefs.SetString(prefUserAgent, strings.TrimSpace(userAgentEntry.Text))
+	}
+
+	testResultLabel := widget.NewLabel("")
+	testBtn := widget.NewButton("Test connection", func() {
+		saveNetworkSettings()
+		url := strings.TrimSpace(urlEntry.Text)
+		if url == "" {
+			testResultLabel.SetText("Paste a URL above first.")
+			return
+		}
+		testResultLabel.SetText("Testing…")
+		go func() {
+			start := time.Now()
+			builder := applyNetworkOptions(ytdlp.New().Simulate().DumpJSON(), prefs)
+			result, err := builder.Run(context.Background(), url)
+			elapsed := time.Since(start)
+
+			fyne.Do(func() {
+				if err != nil {
+					testResultLabel.SetText(fmt.Sprintf("Failed after %s: %v", elapsed.Round(time.Millisecond), err))
+					return
+				}
+				var info struct {
+					AgeLimit     int    `json:"age_limit"`
+					AvailChecked string `json:"availability"`
+				}
+				restricted := "no"
+				if json.Unmarshal([]byte(result.Stdout), &info) == nil {
+					if info.AgeLimit > 0 || info.AvailChecked == "needs_auth" || info.AvailChecked == "premium_only" {
+						restricted = "yes"
+					}
+				}
+				testResultLabel.SetText(fmt.Sprintf("OK in %s — age/region restricted: %s", elapsed.Round(time.Millisecond), restricted))
+			})
+		}()
+	})
+
+	saveBtn := widget.NewButton("Save", func() {
+		saveNetworkSettings()
+		dialog.ShowInformation("Settings", "Network settings saved.", w)
+	})
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("ffmpeg", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		versionLabel,
+		checkBtn,
+		widget.NewLabelWithStyle("Network", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(cookiesPathEntry, browseCookiesBtn),
+		cookiesBrowserSelect,
+		proxyEntry,
+		limitRateEntry,
+		retriesEntry,
+		fragmentRetriesEntry,
+		concurrentFragmentsEntry,
+		userAgentEntry,
+		container.NewHBox(saveBtn, testBtn),
+		testResultLabel,
+	)
+
+	dialog.ShowCustom("Settings", "Close", content, w)
 }
 
 const prefOutputDir = "output_dir"
+const prefQueueState = "queue_state"
+const prefQueueJobOptions = "queue_job_options"
+const prefDownloadArchive = "download_archive"
+const prefFFmpegVersion = "ffmpeg_version"
+const prefCookiesPath = "cookies_path"
+const prefCookiesBrowser = "cookies_browser"
+const prefProxy = "proxy"
+const prefLimitRate = "limit_rate"
+const prefRetries = "retries"
+const prefFragmentRetries = "fragment_retries"
+const prefConcurrentFragments = "concurrent_fragments"
+const prefUserAgent = "user_agent"
+
+// batchConcurrency is the number of playlist items downloaded at once.
+const batchConcurrency = 3
+
+// sponsorblockSkipCountRe matches the segment/chapter count yt-dlp's
+// SponsorBlock/ModifyChapters postprocessors report in a log line, e.g.
+// `[SponsorBlock] Removed 3 SponsorBlock segments` or
+// `[ModifyChapters] Removed 2 chapters`.
+var sponsorblockSkipCountRe = regexp.MustCompile(`(?i)(\d+)\s+(?:sponsorblock\s+)?(?:segments?|chapters?)\b`)
 
 /* -------------------- Helpers -------------------- */
 
@@ -113,6 +355,57 @@ func formatSpeed(speed float64) string {
 	return formatBytes(speed) + "/s"
 }
 
+// codecLabel turns a yt-dlp vcodec string (e.g. "av01.0.05M.08",
+// "vp09.00.50.08", "avc1.640028") into the short name shown in the
+// resolution dropdown.
+func codecLabel(vcodec string) string {
+	switch {
+	case strings.HasPrefix(vcodec, "av01"):
+		return "AV1"
+	case strings.HasPrefix(vcodec, "vp9"), strings.HasPrefix(vcodec, "vp09"):
+		return "VP9"
+	case strings.HasPrefix(vcodec, "avc1"), strings.HasPrefix(vcodec, "h264"):
+		return "H.264"
+	default:
+		return vcodec
+	}
+}
+
+// filterPlaylistItems narrows entries to the 1-based indices described by
+// spec, a yt-dlp --playlist-items style comma list of indices/ranges such
+// as "1-5,8". Malformed tokens are skipped rather than rejecting the batch.
+func filterPlaylistItems(entries []playlistEntry, spec string) []playlistEntry {
+	keep := make(map[int]bool)
+	for _, tok := range strings.Split(spec, ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			continue
+		}
+		if lo, hi, ok := strings.Cut(tok, "-"); ok {
+			from, err1 := strconv.Atoi(strings.TrimSpace(lo))
+			to, err2 := strconv.Atoi(strings.TrimSpace(hi))
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			for i := from; i <= to; i++ {
+				keep[i] = true
+			}
+			continue
+		}
+		if n, err := strconv.Atoi(tok); err == nil {
+			keep[n] = true
+		}
+	}
+
+	out := make([]playlistEntry, 0, len(entries))
+	for i, e := range entries {
+		if keep[i+1] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 /* -------------------- Main -------------------- */
 
 func main() {
@@ -131,7 +424,7 @@ func main() {
 	}
 	/* -------------------- UI -------------------- */
 	go func() {
-		err := checkAndInstallDeps(w)
+		err := checkAndInstallDeps(a, w)
 		if err != nil {
 			fyne.Do(func() {
 				var msg string
@@ -159,28 +452,98 @@ func main() {
 	audioSelect.SetSelected("mp3")
 	audioSelect.Disable()
 
-	downloadType.OnChanged = func(s string) {
-		if s == "Video" {
-			resSelect.Enable()
-			audioSelect.Disable()
-		} else {
-			resSelect.Disable()
-			audioSelect.Enable()
+	/* -------------------- Format Selection -------------------- */
+
+	selectorNames := []string{}
+	selectorByName := map[string]formats.Selector{}
+	for _, s := range formats.Selectors() {
+		selectorNames = append(selectorNames, s.Name())
+		selectorByName[s.Name()] = s
+	}
+	containerSelect := widget.NewSelect(selectorNames, nil)
+	containerSelect.SetSelected(selectorNames[0])
+
+	hdrCheck := widget.NewCheck("Prefer HDR", nil)
+	fps60Check := widget.NewCheck("Prefer 60fps", nil)
+
+	maxFilesizeEntry := widget.NewEntry()
+	maxFilesizeEntry.SetPlaceHolder("Max filesize (MiB, blank = no cap)")
+
+	maxDurationEntry := widget.NewEntry()
+	maxDurationEntry.SetPlaceHolder("Max duration (seconds, blank = no cap)")
+
+	currentFormatOptions := func() formats.FormatOptions {
+		opts := formats.FormatOptions{
+			PreferHDR:   hdrCheck.Checked,
+			Prefer60fps: fps60Check.Checked,
+		}
+		if v, err := strconv.ParseInt(strings.TrimSpace(maxFilesizeEntry.Text), 10, 64); err == nil {
+			opts.MaxFilesizeMiB = v
+		}
+		if resSelect.Selected != "" {
+			selected := strings.Split(resSelect.Selected, " ")[0]
+			if h, err := strconv.Atoi(strings.TrimSuffix(selected, "p")); err == nil {
+				opts.MaxHeight = h
+			}
 		}
+		return opts
 	}
 
-	progressBar := widget.NewProgressBar()
-	statusLabel := widget.NewLabel("Idle")
-	speedLabel := widget.NewLabel("")
+	buildVideoFormat := func() string {
+		sel := selectorByName[containerSelect.Selected]
+		if sel == nil {
+			sel = formats.MP4H264Selector{}
+		}
+		return sel.BuildFormatString(currentFormatOptions())
+	}
 
-	downloadBtn := widget.NewButton("Download", nil)
-	downloadBtn.Disable()
+	matchFilter := func() string {
+		secs := strings.TrimSpace(maxDurationEntry.Text)
+		if secs == "" {
+			return ""
+		}
+		return "duration <= " + secs
+	}
 
-	cancelBtn := widget.NewButton("Cancel", nil)
-	cancelBtn.Disable()
+	/* -------------------- Post-processing -------------------- */
+
+	embedThumbCheck := widget.NewCheck("Embed thumbnail", nil)
+	embedMetaCheck := widget.NewCheck("Embed metadata & chapters", nil)
+
+	writeSubsCheck := widget.NewCheck("Download subtitles", nil)
+	embedSubsCheck := widget.NewCheck("Embed subtitles", nil)
+	embedSubsCheck.Disable()
+	subLangsEntry := widget.NewEntry()
+	subLangsEntry.SetText("en")
+	subLangsEntry.Disable()
+
+	writeSubsCheck.OnChanged = func(v bool) {
+		if v {
+			embedSubsCheck.Enable()
+			subLangsEntry.Enable()
+		} else {
+			embedSubsCheck.SetChecked(false)
+			embedSubsCheck.Disable()
+			subLangsEntry.Disable()
+		}
+	}
+
+	splitChaptersCheck := widget.NewCheck("Split by chapter", nil)
+	normalizeLoudnessCheck := widget.NewCheck("Normalize loudness (audio only)", nil)
+	normalizeLoudnessCheck.Disable()
+
+	currentPostOptions := func() postprocess.Options {
+		return postprocess.Options{
+			EmbedThumbnail:    embedThumbCheck.Checked,
+			EmbedMetadata:     embedMetaCheck.Checked,
+			WriteSubs:         writeSubsCheck.Checked,
+			EmbedSubs:         embedSubsCheck.Checked,
+			SubLangs:          strings.TrimSpace(subLangsEntry.Text),
+			SplitChapters:     splitChaptersCheck.Checked,
+			NormalizeLoudness: normalizeLoudnessCheck.Checked,
+		}
+	}
 
-	titleLabel := widget.NewLabel("")
-	titleLabel.Wrapping = fyne.TextWrapWord
 	/* -------------------- Output Dir -------------------- */
 
 	outputDir := prefs.String(prefOutputDir)
@@ -212,6 +575,413 @@ func main() {
 	updateOutputUI()
 
 	changeDirBtn := widget.NewButton("Change Download Location", selectDirectory)
+	settingsBtn := widget.NewButton("Settings", func() { showSettingsDialog(a, w, urlEntry) })
+
+	/* -------------------- SponsorBlock -------------------- */
+
+	sponsorblockCategories := []string{
+		"sponsor", "intro", "outro", "selfpromo",
+		"interaction", "music_offtopic", "preview", "filler",
+	}
+
+	sponsorblockCheck := widget.NewCheck("Skip sponsors / non-music (SponsorBlock)", nil)
+
+	sponsorblockGroup := widget.NewCheckGroup(sponsorblockCategories, nil)
+	sponsorblockGroup.SetSelected([]string{"sponsor", "selfpromo", "interaction"})
+	sponsorblockGroup.Disable()
+
+	splitBySponsorblockCheck := widget.NewCheck("Split kept chapters into separate files (audio only)", nil)
+	splitBySponsorblockCheck.Disable()
+
+	sponsorblockCheck.OnChanged = func(v bool) {
+		if v {
+			sponsorblockGroup.Enable()
+			if downloadType.Selected == "Audio" {
+				splitBySponsorblockCheck.Enable()
+			}
+		} else {
+			sponsorblockGroup.Disable()
+			splitBySponsorblockCheck.SetChecked(false)
+			splitBySponsorblockCheck.Disable()
+		}
+	}
+
+	// applySponsorblock wires the selected categories onto builder, marking
+	// chapters for the kept segments and, in audio mode, splitting each kept
+	// chapter into its own output file.
+	applySponsorblock := func(builder *ytdlp.Command) *ytdlp.Command {
+		if !sponsorblockCheck.Checked || len(sponsorblockGroup.Selected) == 0 {
+			return builder
+		}
+		removed := make(map[string]bool, len(sponsorblockGroup.Selected))
+		for _, c := range sponsorblockGroup.Selected {
+			removed[c] = true
+		}
+		builder = builder.SponsorblockRemove(strings.Join(sponsorblockGroup.Selected, ","))
+
+		// A category can't be both removed and marked, so mark chapters for
+		// every category the user didn't ask to remove instead.
+		var kept []string
+		for _, c := range sponsorblockCategories {
+			if !removed[c] {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) > 0 {
+			builder = builder.SponsorblockMark(strings.Join(kept, ","))
+		}
+		if downloadType.Selected == "Audio" && splitBySponsorblockCheck.Checked {
+			builder = builder.SplitChapters().
+				Output("chapter:"+outputDir+"/%(title)s - %(section_number)02d - %(section_title)s.%(ext)s")
+		}
+		return builder
+	}
+
+	// sponsorSkipSummary best-effort sums the segment/chapter counts out of
+	// yt-dlp's own "[SponsorBlock]"/"[ModifyChapters]" postprocessor log
+	// lines for display in statusLabel, rather than counting every mention
+	// of the word "sponsorblock" (which also matches banners and category
+	// names and so overcounts wildly).
+	sponsorSkipSummary := func(logText string) string {
+		if !sponsorblockCheck.Checked {
+			return ""
+		}
+		total := 0
+		for _, line := range strings.Split(logText, "\n") {
+			if !strings.Contains(line, "[SponsorBlock]") && !strings.Contains(line, "[ModifyChapters]") {
+				continue
+			}
+			m := sponsorblockSkipCountRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			if n, err := strconv.Atoi(m[1]); err == nil {
+				total += n
+			}
+		}
+		if total == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" (%d SponsorBlock segments skipped)", total)
+	}
+
+	downloadType.OnChanged = func(s string) {
+		if s == "Video" {
+			resSelect.Enable()
+			audioSelect.Disable()
+			normalizeLoudnessCheck.SetChecked(false)
+			normalizeLoudnessCheck.Disable()
+			splitBySponsorblockCheck.SetChecked(false)
+			splitBySponsorblockCheck.Disable()
+		} else {
+			resSelect.Disable()
+			audioSelect.Enable()
+			normalizeLoudnessCheck.Enable()
+			if sponsorblockCheck.Checked {
+				splitBySponsorblockCheck.Enable()
+			}
+		}
+	}
+
+	progressBar := widget.NewProgressBar()
+	statusLabel := widget.NewLabel("Idle")
+	speedLabel := widget.NewLabel("")
+
+	downloadBtn := widget.NewButton("Download", nil)
+	downloadBtn.Disable()
+
+	cancelBtn := widget.NewButton("Cancel", nil)
+	cancelBtn.Disable()
+
+	titleLabel := widget.NewLabel("")
+	titleLabel.Wrapping = fyne.TextWrapWord
+
+	/* -------------------- Playlist / Batch Queue -------------------- */
+
+	playlistItemsEntry := widget.NewEntry()
+	playlistItemsEntry.SetPlaceHolder("Playlist items, e.g. 1-5,8 (blank = all)")
+
+	archiveCheck := widget.NewCheck("Skip already-downloaded items (archive)", nil)
+	archiveCheck.SetChecked(prefs.Bool(prefDownloadArchive))
+	archiveCheck.OnChanged = func(v bool) { prefs.SetBool(prefDownloadArchive, v) }
+
+	queueList := container.NewVBox()
+	queueScroll := container.NewVScroll(queueList)
+	queueScroll.SetMinSize(fyne.NewSize(480, 200))
+	queueScroll.Hide()
+
+	playlistControls := container.NewVBox(playlistItemsEntry, archiveCheck)
+	playlistControls.Hide()
+
+	var batchQueue *queue.Queue
+	var batchCancel context.CancelFunc
+	var batchCtx context.Context
+	var pendingEntries []playlistEntry
+	isPlaylist := false
+
+	// queueRows mirrors batchQueue.Items so the refresh ticker in runBatch
+	// can push each item's Progress/Status onto its row widgets.
+	type queueRowWidgets struct {
+		item   *queue.QueueItem
+		bar    *widget.ProgressBar
+		status *widget.Label
+	}
+	var queueRows []*queueRowWidgets
+
+	archivePath := func() string {
+		if !archiveCheck.Checked || outputDir == "" {
+			return ""
+		}
+		return outputDir + "/.predator-archive.txt"
+	}
+
+	// batchJobOptions snapshots every UI widget value a queue worker needs,
+	// taken on the UI goroutine before a batch starts. Queue items run on up
+	// to batchConcurrency worker goroutines concurrently, so runQueueItem
+	// must never read widget state directly off of those goroutines. Fields
+	// are exported and JSON-tagged so a running batch's options can be
+	// persisted alongside prefQueueState and restored verbatim on relaunch,
+	// instead of re-snapshotting whatever the UI defaults to at that point.
+	type batchJobOptions struct {
+		OutputDir           string              `json:"outputDir"`
+		Audio               bool                `json:"audio"`
+		AudioFormat         string              `json:"audioFormat"`
+		VideoFormat         string              `json:"videoFormat"`
+		ArchivePath         string              `json:"archivePath"`
+		MatchFilter         string              `json:"matchFilter"`
+		Post                postprocess.Options `json:"post"`
+		SponsorRemove       []string            `json:"sponsorRemove,omitempty"`
+		SponsorMark         []string            `json:"sponsorMark,omitempty"`
+		SplitBySponsorblock bool                `json:"splitBySponsorblock"`
+	}
+
+	snapshotBatchJobOptions := func() batchJobOptions {
+		opts := batchJobOptions{
+			OutputDir:   outputDir,
+			Audio:       downloadType.Selected == "Audio",
+			AudioFormat: audioSelect.Selected,
+			VideoFormat: buildVideoFormat(),
+			ArchivePath: archivePath(),
+			MatchFilter: matchFilter(),
+			Post:        currentPostOptions(),
+		}
+		if sponsorblockCheck.Checked && len(sponsorblockGroup.Selected) > 0 {
+			removed := make(map[string]bool, len(sponsorblockGroup.Selected))
+			for _, c := range sponsorblockGroup.Selected {
+				removed[c] = true
+			}
+			opts.SponsorRemove = append([]string(nil), sponsorblockGroup.Selected...)
+			for _, c := range sponsorblockCategories {
+				if !removed[c] {
+					opts.SponsorMark = append(opts.SponsorMark, c)
+				}
+			}
+			opts.SplitBySponsorblock = splitBySponsorblockCheck.Checked
+		}
+		return opts
+	}
+
+	// buildBatchRunItem returns a queue.RunFunc closed over a snapshot of
+	// opts rather than live widgets, safe to call from any worker goroutine.
+	buildBatchRunItem := func(opts batchJobOptions) queue.RunFunc {
+		return func(ctx context.Context, it *queue.QueueItem) error {
+			builder := ytdlp.New().
+				Output(opts.OutputDir + "/%(title)s.%(ext)s").
+				Print("after_move:filepath").
+				ProgressFunc(200*time.Millisecond, func(p ytdlp.ProgressUpdate) {
+					it.SetProgress(p.Percent() / 100)
+				})
+			if opts.Audio {
+				builder = builder.ExtractAudio().AudioFormat(opts.AudioFormat)
+			} else {
+				builder = builder.Format(opts.VideoFormat).MergeOutputFormat("mp4")
+			}
+			if opts.ArchivePath != "" {
+				builder = builder.DownloadArchive(opts.ArchivePath)
+			}
+			if opts.MatchFilter != "" {
+				builder = builder.MatchFilter(opts.MatchFilter)
+			}
+			builder = postprocess.Apply(builder, opts.Post)
+			if len(opts.SponsorRemove) > 0 {
+				builder = builder.SponsorblockRemove(strings.Join(opts.SponsorRemove, ","))
+				if len(opts.SponsorMark) > 0 {
+					builder = builder.SponsorblockMark(strings.Join(opts.SponsorMark, ","))
+				}
+				if opts.Audio && opts.SplitBySponsorblock {
+					builder = builder.SplitChapters().
+						Output("chapter:" + opts.OutputDir + "/%(title)s - %(section_number)02d - %(section_title)s.%(ext)s")
+				}
+			}
+			builder = applyNetworkOptions(builder, prefs)
+			result, err := builder.Run(ctx, it.URL)
+			if err != nil {
+				return err
+			}
+
+			// Mirror the single-download path: normalize loudness against
+			// the file yt-dlp just produced so "Normalize loudness" isn't
+			// silently a no-op for playlist/batch runs.
+			if opts.Post.NormalizeLoudness && opts.Audio {
+				if finalPath := strings.TrimSpace(result.Stdout); finalPath != "" {
+					if nerr := postprocess.Normalize(ctx, finalPath); nerr != nil {
+						log.Println("loudness normalization failed for", it.URL, ":", nerr)
+					}
+				}
+			}
+			return nil
+		}
+	}
+
+	var currentRunItem queue.RunFunc
+
+	newQueueRow := func(it *queue.QueueItem) fyne.CanvasObject {
+		label := widget.NewLabel(it.Title)
+		label.Wrapping = fyne.TextWrapWord
+		bar := widget.NewProgressBar()
+		status := widget.NewLabel(string(it.GetStatus()))
+
+		var pauseBtn, cancelRowBtn *widget.Button
+		pauseBtn = widget.NewButton("Pause", func() {
+			switch it.GetStatus() {
+			case queue.StatusPaused, queue.StatusFailed, queue.StatusCanceled:
+				pauseBtn.SetText("Pause")
+				batchQueue.Resume(batchCtx, it, currentRunItem)
+			default:
+				pauseBtn.SetText("Resume")
+				batchQueue.Pause(it)
+			}
+			status.SetText(string(it.GetStatus()))
+		})
+		cancelRowBtn = widget.NewButton("Cancel", func() {
+			batchQueue.CancelItem(it)
+			status.SetText(string(it.GetStatus()))
+		})
+
+		queueRows = append(queueRows, &queueRowWidgets{item: it, bar: bar, status: status})
+		return container.NewVBox(label, bar, container.NewHBox(status, pauseBtn, cancelRowBtn))
+	}
+
+	// persistQueueState snapshots batchQueue's current items to
+	// prefQueueState. Called on every runBatch tick (not just once the
+	// whole batch reaches a terminal status) so a process kill/crash mid-run
+	// leaves pending/downloading items on disk for the next launch to
+	// resume, instead of only ever persisting an already-finished batch.
+	persistQueueState := func() {
+		if batchQueue == nil {
+			return
+		}
+		if data, err := batchQueue.Marshal(); err == nil {
+			prefs.SetString(prefQueueState, data)
+		}
+	}
+
+	// runBatch drives batchQueue to completion, polling each item's
+	// Progress/Status onto its row widgets and persisting queue state until
+	// the batch finishes.
+	runBatch := func(ctx context.Context, run queue.RunFunc) {
+		refresh := func() {
+			fyne.Do(func() {
+				for _, row := range queueRows {
+					row.bar.SetValue(row.item.GetProgress())
+					row.status.SetText(string(row.item.GetStatus()))
+				}
+			})
+		}
+
+		done := make(chan struct{})
+		go func() {
+			batchQueue.Start(ctx, run)
+			close(done)
+		}()
+
+		ticker := time.NewTicker(300 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				refresh()
+				persistQueueState()
+				fyne.Do(func() {
+					statusLabel.SetText("Playlist download finished")
+					downloadBtn.Enable()
+				})
+				return
+			case <-ticker.C:
+				refresh()
+				persistQueueState()
+			}
+		}
+	}
+
+	startBatch := func(entries []playlistEntry) {
+		if sel := strings.TrimSpace(playlistItemsEntry.Text); sel != "" {
+			entries = filterPlaylistItems(entries, sel)
+		}
+		batchQueue = queue.NewQueue(batchConcurrency)
+		queueList.Objects = nil
+		queueRows = nil
+		for _, e := range entries {
+			it := batchQueue.Add(e.URL)
+			it.Title = e.Title
+			queueList.Add(newQueueRow(it))
+		}
+		queueList.Refresh()
+		queueScroll.Show()
+
+		jobOptions := snapshotBatchJobOptions()
+		if data, err := json.Marshal(jobOptions); err == nil {
+			prefs.SetString(prefQueueJobOptions, string(data))
+		}
+		currentRunItem = buildBatchRunItem(jobOptions)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		batchCancel = cancel
+		batchCtx = ctx
+
+		go runBatch(ctx, currentRunItem)
+	}
+
+	// Restore an interrupted batch from the last run so it can pick back up
+	// without the user having to re-paste the playlist URL. The options the
+	// batch was originally started with (audio/video, format selector,
+	// post-processing, SponsorBlock) are restored alongside the queue items
+	// rather than re-read from the UI's current (default) widget values, so
+	// a resumed batch keeps running with the settings the user picked.
+	if data := prefs.String(prefQueueState); data != "" {
+		restored := queue.NewQueue(batchConcurrency)
+		if err := restored.Unmarshal(data); err != nil {
+			log.Println("failed to restore queue state:", err)
+		} else if len(restored.Items) > 0 {
+			batchQueue = restored
+			queueList.Objects = nil
+			queueRows = nil
+			for _, it := range batchQueue.Items {
+				queueList.Add(newQueueRow(it))
+			}
+			queueList.Refresh()
+			queueScroll.Show()
+			playlistControls.Show()
+			isPlaylist = true
+			statusLabel.SetText("Resuming interrupted playlist download...")
+
+			jobOptions := snapshotBatchJobOptions()
+			if saved := prefs.String(prefQueueJobOptions); saved != "" {
+				var restoredOpts batchJobOptions
+				if err := json.Unmarshal([]byte(saved), &restoredOpts); err != nil {
+					log.Println("failed to restore queue job options, using current settings:", err)
+				} else {
+					jobOptions = restoredOpts
+				}
+			}
+			currentRunItem = buildBatchRunItem(jobOptions)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			batchCancel = cancel
+			batchCtx = ctx
+			go runBatch(ctx, currentRunItem)
+		}
+	}
 
 	/* -------------------- Dynamic Fetch -------------------- */
 
@@ -237,9 +1007,20 @@ func main() {
 				statusLabel.SetText("Fetching video info...")
 				resSelect.Disable()
 				downloadBtn.Disable()
+				playlistControls.Hide()
+				queueScroll.Hide()
 			})
 
-			go fetchVideoInfo(text, resolutions, resSelect, statusLabel, titleLabel, downloadBtn, &fetching)
+			go fetchVideoInfo(text, resolutions, resSelect, statusLabel, titleLabel, downloadBtn, &fetching, prefs, func(entries []playlistEntry) {
+				isPlaylist = true
+				pendingEntries = entries
+				playlistControls.Show()
+				statusLabel.SetText(fmt.Sprintf("Playlist detected: %d items", len(entries)))
+				downloadBtn.Enable()
+			}, func() {
+				isPlaylist = false
+				pendingEntries = nil
+			})
 		})
 	}
 
@@ -258,6 +1039,14 @@ func main() {
 			return
 		}
 
+		if isPlaylist {
+			downloadBtn.Disable()
+			cancelBtn.Enable()
+			statusLabel.SetText("Starting playlist download...")
+			startBatch(pendingEntries)
+			return
+		}
+
 		ctx, cancel := context.WithCancel(context.Background())
 		cancelFunc = cancel
 		atomic.StoreInt32(&downloading, 1)
@@ -331,36 +1120,54 @@ func main() {
 
 			var err error
 
-			if downloadType.Selected == "Video" {
-				selected := strings.Split(resSelect.Selected, " ")[0]
-				res := strings.TrimSuffix(selected, "p")
-
-				var format string
-				if selected != "best" {
-					format = fmt.Sprintf(
-						"bestvideo[ext=mp4][height<=%s]+bestaudio[ext=m4a]/mp4/"+
-							"bestvideo[height<=%s]+bestaudio/best",
-						res, res,
-					)
-				} else {
-					format = "bestvideo[ext=mp4]+bestaudio[ext=m4a]/mp4/bestvideo+bestaudio/best"
-				}
+			postOpts := currentPostOptions()
+			var result *ytdlp.Result
 
-				_, err = ytdlp.New().
-					Format(format).
+			if downloadType.Selected == "Video" {
+				builder := ytdlp.New().
+					Format(buildVideoFormat()).
 					MergeOutputFormat("mp4").
 					Output(outputDir+"/%(title)s.%(ext)s").
-					ProgressFunc(200*time.Millisecond, updateProgress).
-					Run(ctx, url)
+					Print("after_move:filepath").
+					ProgressFunc(200*time.Millisecond, updateProgress)
+				if mf := matchFilter(); mf != "" {
+					builder = builder.MatchFilter(mf)
+				}
+				builder = postprocess.Apply(builder, postOpts)
+				builder = applySponsorblock(builder)
+				builder = applyNetworkOptions(builder, prefs)
+				result, err = builder.Run(ctx, url)
 
 			} else {
-				_, err = ytdlp.New().
+				builder := ytdlp.New().
 					ExtractAudio().
 					AudioFormat(audioSelect.Selected).
 					Output(outputDir+"/%(title)s.%(ext)s").
-					ProgressFunc(200*time.Millisecond, updateProgress).
-					Run(ctx, url)
+					Print("after_move:filepath").
+					ProgressFunc(200*time.Millisecond, updateProgress)
+				builder = postprocess.Apply(builder, postOpts)
+				builder = applySponsorblock(builder)
+				builder = applyNetworkOptions(builder, prefs)
+				result, err = builder.Run(ctx, url)
+			}
+
+			var finalPath, runLog string
+			if err == nil && result != nil {
+				finalPath = strings.TrimSpace(result.Stdout)
+				runLog = result.Stdout + result.Stderr
+			}
+
+			if err == nil && postOpts.NormalizeLoudness && downloadType.Selected == "Audio" && finalPath != "" {
+				if nerr := postprocess.Normalize(ctx, finalPath); nerr != nil {
+					log.Println("loudness normalization failed:", nerr)
+				}
+			}
+
+			var probe *postprocess.ProbeResult
+			if err == nil && finalPath != "" {
+				probe, _ = postprocess.Probe(ctx, finalPath)
 			}
+			sponsorSummary := sponsorSkipSummary(runLog)
 
 			fyne.Do(func() {
 				cancelBtn.Disable()
@@ -377,7 +1184,11 @@ func main() {
 					progressBar.SetValue(0)
 				} else {
 					progressBar.SetValue(1)
-					statusLabel.SetText("Download completed")
+					if probe != nil {
+						statusLabel.SetText(fmt.Sprintf("Done — %s, %s, %s%s", probe.Duration, probe.Bitrate, probe.Codec, sponsorSummary))
+					} else {
+						statusLabel.SetText("Download completed" + sponsorSummary)
+					}
 				}
 			})
 		}()
@@ -387,6 +1198,12 @@ func main() {
 		if cancelFunc != nil {
 			cancelFunc()
 		}
+		if batchCancel != nil {
+			batchCancel()
+		}
+		if batchQueue != nil {
+			batchQueue.CancelAll()
+		}
 	}
 
 	/* -------------------- Layout -------------------- */
@@ -398,20 +1215,50 @@ func main() {
 		downloadType,
 		resSelect,
 		audioSelect,
+		containerSelect,
+		container.NewHBox(hdrCheck, fps60Check),
+		maxFilesizeEntry,
+		maxDurationEntry,
+		embedThumbCheck,
+		embedMetaCheck,
+		container.NewHBox(writeSubsCheck, embedSubsCheck, subLangsEntry),
+		splitChaptersCheck,
+		normalizeLoudnessCheck,
+		sponsorblockCheck,
+		sponsorblockGroup,
+		splitBySponsorblockCheck,
 		outputDirLabel,
 		changeDirBtn,
+		settingsBtn,
+		playlistControls,
 		container.NewHBox(downloadBtn, cancelBtn),
 		statusLabel,
 		speedLabel,
 		progressBar,
+		queueScroll,
 	)
 
 	w.SetContent(container.NewScroll(content))
+
+	// Persist in-flight queue state on shutdown too, since a batch can be
+	// interrupted by the window closing rather than the process being
+	// killed outright.
+	w.SetCloseIntercept(func() {
+		persistQueueState()
+		w.Close()
+	})
+
 	w.ShowAndRun()
 }
 
 /* -------------------- Fetch Function -------------------- */
 
+// playlistEntry is one video within a detected playlist/channel result.
+type playlistEntry struct {
+	URL   string
+	Title string
+}
+
 func fetchVideoInfo(
 	url string,
 	resolutions []string,
@@ -420,11 +1267,15 @@ func fetchVideoInfo(
 	titleLabel *widget.Label,
 	downloadBtn *widget.Button,
 	fetching *int32,
+	prefs fyne.Preferences,
+	onPlaylist func(entries []playlistEntry),
+	onSingle func(),
 ) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	result, err := ytdlp.New().DumpJSON().Run(ctx, url)
+	builder := applyNetworkOptions(ytdlp.New().DumpJSON().YesPlaylist(), prefs)
+	result, err := builder.Run(ctx, url)
 
 	fyne.Do(func() {
 		defer atomic.StoreInt32(fetching, 0)
@@ -435,12 +1286,21 @@ func fetchVideoInfo(
 		}
 
 		var info struct {
+			Type    string `json:"_type"`
 			Title   string `json:"title"`
+			Entries []struct {
+				URL   string `json:"url"`
+				Title string `json:"title"`
+			} `json:"entries"`
 			Formats []struct {
-				Height         *int   `json:"height"`
-				Filesize       *int64 `json:"filesize"`
-				FilesizeApprox *int64 `json:"filesize_approx"`
-				Vcodec         string `json:"vcodec"`
+				Height         *int     `json:"height"`
+				Filesize       *int64   `json:"filesize"`
+				FilesizeApprox *int64   `json:"filesize_approx"`
+				Vcodec         string   `json:"vcodec"`
+				Acodec         string   `json:"acodec"`
+				FPS            *float64 `json:"fps"`
+				DynamicRange   string   `json:"dynamic_range"`
+				TBR            *float64 `json:"tbr"`
 			} `json:"formats"`
 		}
 
@@ -448,26 +1308,62 @@ func fetchVideoInfo(
 			statusLabel.SetText("Failed to parse info")
 			return
 		}
+
+		if info.Type == "playlist" {
+			entries := make([]playlistEntry, 0, len(info.Entries))
+			for _, e := range info.Entries {
+				entries = append(entries, playlistEntry{URL: e.URL, Title: e.Title})
+			}
+			titleLabel.SetText("Playlist : " + info.Title)
+			onPlaylist(entries)
+			return
+		}
+		onSingle()
 		titleLabel.SetText("Title : " + info.Title)
+
 		resMap := make(map[string]string)
+		bestTBR := make(map[string]float64)
 		for _, f := range info.Formats {
-			if f.Vcodec != "none" && f.Height != nil {
-				res := fmt.Sprintf("%dp", *f.Height)
-				if f.Filesize != nil {
-					resMap[res] = formatBytes(float64(*f.Filesize))
-				} else if f.FilesizeApprox != nil {
-					resMap[res] = "~" + formatBytes(float64(*f.FilesizeApprox))
-				}
+			if f.Vcodec == "" || f.Vcodec == "none" || f.Height == nil {
+				continue
+			}
+			res := fmt.Sprintf("%dp", *f.Height)
+
+			tbr := 0.0
+			if f.TBR != nil {
+				tbr = *f.TBR
 			}
+			if prev, ok := bestTBR[res]; ok && tbr <= prev {
+				continue
+			}
+			bestTBR[res] = tbr
+
+			detail := res
+			if f.FPS != nil && *f.FPS >= 50 {
+				detail += "60"
+			}
+			detail += " " + codecLabel(f.Vcodec)
+			if strings.Contains(strings.ToUpper(f.DynamicRange), "HDR") {
+				detail += " HDR"
+			}
+
+			size := "Unknown"
+			if f.Filesize != nil {
+				size = formatBytes(float64(*f.Filesize))
+			} else if f.FilesizeApprox != nil {
+				size = "~" + formatBytes(float64(*f.FilesizeApprox))
+			}
+
+			resMap[res] = detail + " — " + size
 		}
 
 		opts := []string{}
 		for _, r := range resolutions {
-			size := "Unknown"
+			detail := "Unknown"
 			if s, ok := resMap[r]; ok {
-				size = s
+				detail = s
 			}
-			opts = append(opts, fmt.Sprintf("%s (%s)", r, size))
+			opts = append(opts, fmt.Sprintf("%s (%s)", r, detail))
 		}
 
 		resSelect.Options = opts
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/lrstanley/go-ytdlp"
+)
+
+func TestFilterPlaylistItems(t *testing.T) {
+	entries := []playlistEntry{
+		{URL: "u1", Title: "one"},
+		{URL: "u2", Title: "two"},
+		{URL: "u3", Title: "three"},
+		{URL: "u4", Title: "four"},
+		{URL: "u5", Title: "five"},
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		want []playlistEntry
+	}{
+		{"single index", "2", []playlistEntry{entries[1]}},
+		{"range", "1-3", entries[0:3]},
+		{"range plus index", "1-2,5", []playlistEntry{entries[0], entries[1], entries[4]}},
+		{"whitespace", " 1 - 2 , 4 ", []playlistEntry{entries[0], entries[1], entries[3]}},
+		{"malformed token skipped", "x,2", []playlistEntry{entries[1]}},
+		{"malformed range skipped", "1-x,3", []playlistEntry{entries[2]}},
+		{"out of range index dropped", "1,99", []playlistEntry{entries[0]}},
+		{"empty spec keeps nothing", "", []playlistEntry{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := filterPlaylistItems(entries, tc.spec)
+			if len(got) == 0 && len(tc.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("filterPlaylistItems(%q) = %v, want %v", tc.spec, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyNetworkOptions(t *testing.T) {
+	a := test.NewApp()
+	defer test.NewApp() // reset the global test app for later tests
+	prefs := a.Preferences()
+
+	prefs.SetString(prefCookiesPath, "/home/user/cookies.txt")
+	prefs.SetString(prefProxy, "socks5://127.0.0.1:9050")
+	prefs.SetString(prefLimitRate, "2M")
+	prefs.SetInt(prefRetries, 5)
+	prefs.SetInt(prefFragmentRetries, 5)
+	prefs.SetInt(prefConcurrentFragments, 4)
+	prefs.SetString(prefUserAgent, "Predator/1.0")
+
+	flags := applyNetworkOptions(ytdlp.New(), prefs).GetFlagConfig()
+
+	if flags.Filesystem.Cookies == nil || *flags.Filesystem.Cookies != "/home/user/cookies.txt" {
+		t.Errorf("Cookies = %v, want /home/user/cookies.txt", flags.Filesystem.Cookies)
+	}
+	if flags.Network.Proxy == nil || *flags.Network.Proxy != "socks5://127.0.0.1:9050" {
+		t.Errorf("Proxy = %v, want socks5://127.0.0.1:9050", flags.Network.Proxy)
+	}
+	if flags.Download.LimitRate == nil || *flags.Download.LimitRate != "2M" {
+		t.Errorf("LimitRate = %v, want 2M", flags.Download.LimitRate)
+	}
+	if flags.Download.Retries == nil || *flags.Download.Retries != "5" {
+		t.Errorf("Retries = %v, want 5", flags.Download.Retries)
+	}
+	if flags.Download.ConcurrentFragments == nil || *flags.Download.ConcurrentFragments != 4 {
+		t.Errorf("ConcurrentFragments = %v, want 4", flags.Download.ConcurrentFragments)
+	}
+	if flags.Workarounds.UserAgent == nil || *flags.Workarounds.UserAgent != "Predator/1.0" {
+		t.Errorf("UserAgent = %v, want Predator/1.0", flags.Workarounds.UserAgent)
+	}
+}
+
+func TestApplyNetworkOptionsDefaults(t *testing.T) {
+	a := test.NewApp()
+	defer test.NewApp()
+	prefs := a.Preferences()
+
+	flags := applyNetworkOptions(ytdlp.New(), prefs).GetFlagConfig()
+
+	// Retries/FragmentRetries fall back to 10 even with nothing persisted,
+	// matching prefs.IntWithFallback(..., 10) in applyNetworkOptions.
+	if flags.Download.Retries == nil || *flags.Download.Retries != "10" {
+		t.Errorf("Retries = %v, want 10 (default)", flags.Download.Retries)
+	}
+	if flags.Network.Proxy != nil {
+		t.Errorf("Proxy = %v, want unset", flags.Network.Proxy)
+	}
+}
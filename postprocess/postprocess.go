@@ -0,0 +1,182 @@
+// Package postprocess covers everything Predator does to a finished
+// download: telling yt-dlp which embeds/splits to produce, normalizing
+// audio loudness with ffmpeg, and probing the result with ffprobe for the
+// summary shown in statusLabel.
+package postprocess
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/lrstanley/go-ytdlp"
+)
+
+// Options mirrors the post-processing toggles exposed in the UI.
+type Options struct {
+	EmbedThumbnail bool
+	EmbedMetadata  bool
+	WriteSubs      bool
+	EmbedSubs      bool
+	SubLangs       string // e.g. "en,ja"
+	SplitChapters  bool
+
+	// NormalizeLoudness only applies in audio mode; it runs after Run via
+	// Normalize rather than as a yt-dlp flag.
+	NormalizeLoudness bool
+}
+
+// Apply chains the requested yt-dlp flags onto builder and returns it.
+func Apply(builder *ytdlp.Command, opts Options) *ytdlp.Command {
+	if opts.EmbedThumbnail {
+		builder = builder.EmbedThumbnail()
+	}
+	if opts.EmbedMetadata {
+		builder = builder.EmbedMetadata().EmbedChapters()
+	}
+	if opts.WriteSubs {
+		builder = builder.WriteSubs()
+		if opts.SubLangs != "" {
+			builder = builder.SubLangs(opts.SubLangs)
+		}
+		if opts.EmbedSubs {
+			builder = builder.EmbedSubs()
+		}
+	}
+	if opts.SplitChapters {
+		builder = builder.SplitChapters()
+	}
+	return builder
+}
+
+// loudnessStats is the subset of ffmpeg's loudnorm first-pass JSON report
+// needed to drive the second, linear pass.
+type loudnessStats struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// Normalize loudness-normalizes the audio file at path in place using a
+// two-pass EBU R128 loudnorm filter (measure, then apply with the
+// measured values so the result is linear rather than dynamically
+// compressed). Target: integrated -16 LUFS, true peak -1.5 dBTP, LRA 11.
+func Normalize(ctx context.Context, path string) error {
+	const (
+		targetI   = "-16"
+		targetTP  = "-1.5"
+		targetLRA = "11"
+	)
+
+	measureFilter := fmt.Sprintf("loudnorm=I=%s:TP=%s:LRA=%s:print_format=json", targetI, targetTP, targetLRA)
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-i", path, "-af", measureFilter, "-f", "null", "-")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("loudnorm measure pass: %w", err)
+	}
+
+	stats, err := parseLoudnormReport(string(out))
+	if err != nil {
+		return fmt.Errorf("parse loudnorm report: %w", err)
+	}
+
+	tmp := path + ".normalized" + extOf(path)
+	applyFilter := fmt.Sprintf(
+		"loudnorm=I=%s:TP=%s:LRA=%s:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+		targetI, targetTP, targetLRA,
+		stats.InputI, stats.InputTP, stats.InputLRA, stats.InputThresh, stats.TargetOffset,
+	)
+	cmd = exec.CommandContext(ctx, "ffmpeg", "-y", "-hide_banner", "-i", path, "-af", applyFilter, tmp)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("loudnorm apply pass: %w: %s", err, out)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace %s with normalized output: %w", path, err)
+	}
+	return nil
+}
+
+// parseLoudnormReport extracts the JSON block ffmpeg prints at the end of
+// a loudnorm measure pass's stderr output.
+func parseLoudnormReport(output string) (*loudnessStats, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no loudnorm JSON report found in ffmpeg output")
+	}
+	var stats loudnessStats
+	if err := json.Unmarshal([]byte(output[start:end+1]), &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func extOf(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[i:]
+	}
+	return ""
+}
+
+// ProbeResult is the subset of ffprobe's output shown to the user after a
+// download finishes.
+type ProbeResult struct {
+	Duration string
+	Bitrate  string
+	Codec    string
+}
+
+// Probe runs ffprobe against path and summarizes duration/bitrate/codec.
+func Probe(ctx context.Context, path string) (*ProbeResult, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "quiet",
+		"-show_format", "-show_streams",
+		"-print_format", "json",
+		path,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe %s: %w", path, err)
+	}
+
+	var probe struct {
+		Format struct {
+			Duration string `json:"duration"`
+			BitRate  string `json:"bit_rate"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("parse ffprobe output for %s: %w", path, err)
+	}
+
+	var codec string
+	for _, s := range probe.Streams {
+		if s.CodecType == "video" {
+			codec = s.CodecName
+			break
+		}
+	}
+	if codec == "" && len(probe.Streams) > 0 {
+		codec = probe.Streams[0].CodecName
+	}
+
+	result := &ProbeResult{Codec: codec}
+	if secs, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		result.Duration = fmt.Sprintf("%.0fs", secs)
+	}
+	if bps, err := strconv.ParseFloat(probe.Format.BitRate, 64); err == nil {
+		result.Bitrate = fmt.Sprintf("%.0f kbps", bps/1000)
+	}
+	return result, nil
+}
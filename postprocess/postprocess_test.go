@@ -0,0 +1,36 @@
+package postprocess
+
+import "testing"
+
+func TestParseLoudnormReport(t *testing.T) {
+	output := "some ffmpeg banner noise\n" +
+		`{"input_i" : "-23.10", "input_tp" : "-2.00", "input_lra" : "4.00", ` +
+		`"input_thresh" : "-33.50", "target_offset" : "0.90"}` +
+		"\nmore trailing noise"
+
+	stats, err := parseLoudnormReport(output)
+	if err != nil {
+		t.Fatalf("parseLoudnormReport() error = %v", err)
+	}
+	if stats.InputI != "-23.10" || stats.TargetOffset != "0.90" {
+		t.Errorf("parseLoudnormReport() = %+v, missing expected fields", stats)
+	}
+}
+
+func TestParseLoudnormReportNoJSON(t *testing.T) {
+	if _, err := parseLoudnormReport("no json here"); err == nil {
+		t.Fatal("parseLoudnormReport() error = nil, want error for missing JSON block")
+	}
+}
+
+func TestExtOf(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"/tmp/video.mp4", ".mp4"},
+		{"/tmp/no-extension", ""},
+	}
+	for _, tc := range tests {
+		if got := extOf(tc.path); got != tc.want {
+			t.Errorf("extOf(%q) = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package queue implements a concurrency-limited batch download queue used
+// for playlist/channel downloads, where a single URL fans out into many
+// per-video jobs that need independent progress, pause/resume and cancel.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Status is the lifecycle state of a single QueueItem.
+type Status string
+
+const (
+	StatusPending     Status = "pending"
+	StatusDownloading Status = "downloading"
+	StatusPaused      Status = "paused"
+	StatusDone        Status = "done"
+	StatusFailed      Status = "failed"
+	StatusCanceled    Status = "canceled"
+)
+
+// QueueItem tracks one video within a batch download.
+type QueueItem struct {
+	URL      string  `json:"url"`
+	Title    string  `json:"title"`
+	Status   Status  `json:"status"`
+	Progress float64 `json:"progress"`
+	Error    string  `json:"error,omitempty"`
+
+	cancel context.CancelFunc
+	mu     sync.Mutex
+}
+
+// SetProgress updates the item's progress fraction (0..1) and is safe to
+// call from a ytdlp.ProgressFunc callback.
+func (it *QueueItem) SetProgress(p float64) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	it.Progress = p
+}
+
+// GetStatus returns the item's current status. Callers outside this
+// package must use this instead of reading the Status field directly,
+// since runItem/Pause/CancelItem write it under it.mu from other
+// goroutines.
+func (it *QueueItem) GetStatus() Status {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.Status
+}
+
+// GetProgress returns the item's current progress fraction (0..1). Callers
+// outside this package must use this instead of reading the Progress field
+// directly, since SetProgress/runItem write it under it.mu from other
+// goroutines.
+func (it *QueueItem) GetProgress() float64 {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.Progress
+}
+
+// Queue runs a bounded number of QueueItem jobs concurrently.
+type Queue struct {
+	mu          sync.Mutex
+	Items       []*QueueItem
+	Concurrency int
+
+	sem chan struct{}
+}
+
+// NewQueue creates a Queue that runs at most concurrency jobs at once.
+func NewQueue(concurrency int) *Queue {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Queue{
+		Concurrency: concurrency,
+		sem:         make(chan struct{}, concurrency),
+	}
+}
+
+// Add appends a new pending item for url and returns it.
+func (q *Queue) Add(url string) *QueueItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	it := &QueueItem{URL: url, Status: StatusPending}
+	q.Items = append(q.Items, it)
+	return it
+}
+
+// RunFunc downloads a single item, reporting progress via item.SetProgress.
+type RunFunc func(ctx context.Context, item *QueueItem) error
+
+// Start launches run for every pending item, respecting Concurrency, and
+// blocks until every run it launched has returned. An item paused mid-run
+// counts as returned, and runs started through Resume are not waited for.
+func (q *Queue) Start(ctx context.Context, run RunFunc) {
+	var wg sync.WaitGroup
+
+	for _, it := range q.Items {
+		if it.Status != StatusPending {
+			continue
+		}
+		wg.Add(1)
+		q.sem <- struct{}{}
+
+		go func(it *QueueItem) {
+			defer wg.Done()
+			defer func() { <-q.sem }()
+			q.runItem(ctx, it, run)
+		}(it)
+	}
+
+	wg.Wait()
+}
+
+func (q *Queue) runItem(ctx context.Context, it *QueueItem, run RunFunc) {
+	itemCtx, cancel := context.WithCancel(ctx)
+
+	it.mu.Lock()
+	it.cancel = cancel
+	it.Status = StatusDownloading
+	it.mu.Unlock()
+
+	err := run(itemCtx, it)
+
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	it.cancel = nil
+	switch {
+	case itemCtx.Err() == context.Canceled && it.Status == StatusPaused:
+		// left paused by Pause()
+	case itemCtx.Err() == context.Canceled:
+		it.Status = StatusCanceled
+	case err != nil:
+		it.Status = StatusFailed
+		it.Error = err.Error()
+	default:
+		it.Status = StatusDone
+		it.Progress = 1
+	}
+}
+
+// Pause cancels an in-flight item so it can be resumed later; the item is
+// left at whatever Progress it last reported.
+func (q *Queue) Pause(it *QueueItem) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.cancel == nil {
+		return
+	}
+	it.Status = StatusPaused
+	it.cancel()
+}
+
+// Resume re-queues a paused, failed or canceled item as pending and runs it
+// through run immediately, independent of Start's own pass. It returns
+// without blocking even when every Concurrency slot is busy; the semaphore
+// is acquired inside the spawned goroutine instead of the caller.
+func (q *Queue) Resume(ctx context.Context, it *QueueItem, run RunFunc) {
+	it.mu.Lock()
+	it.Status = StatusPending
+	it.Error = ""
+	it.mu.Unlock()
+
+	go func() {
+		q.sem <- struct{}{}
+		defer func() { <-q.sem }()
+		q.runItem(ctx, it, run)
+	}()
+}
+
+// CancelItem stops a single in-flight item for good.
+func (q *Queue) CancelItem(it *QueueItem) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.cancel != nil {
+		it.cancel()
+	}
+	it.Status = StatusCanceled
+}
+
+// CancelAll stops every in-flight item.
+func (q *Queue) CancelAll() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, it := range q.Items {
+		if it.Status == StatusDownloading || it.Status == StatusPending {
+			q.CancelItem(it)
+		}
+	}
+}
+
+// Marshal serializes the queue's items so it can be persisted via
+// fyne.Preferences and resumed on next launch.
+func (q *Queue) Marshal() (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	b, err := json.Marshal(q.Items)
+	if err != nil {
+		return "", fmt.Errorf("marshal queue: %w", err)
+	}
+	return string(b), nil
+}
+
+// Unmarshal restores items from a string previously produced by Marshal.
+// Items left mid-download are reset to pending so Start will retry them.
+func (q *Queue) Unmarshal(data string) error {
+	if data == "" {
+		return nil
+	}
+	var items []*QueueItem
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		return fmt.Errorf("unmarshal queue: %w", err)
+	}
+	for _, it := range items {
+		if it.Status == StatusDownloading {
+			it.Status = StatusPending
+		}
+	}
+	q.mu.Lock()
+	q.Items = items
+	q.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,53 @@
+package queue
+
+import "testing"
+
+func TestMarshalUnmarshal(t *testing.T) {
+	q := NewQueue(2)
+	done := q.Add("https://example.com/done")
+	done.Status = StatusDone
+	done.Progress = 1
+
+	mid := q.Add("https://example.com/mid")
+	mid.Status = StatusDownloading
+	mid.Progress = 0.5
+
+	failed := q.Add("https://example.com/failed")
+	failed.Status = StatusFailed
+	failed.Error = "boom"
+
+	data, err := q.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	restored := NewQueue(2)
+	if err := restored.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if len(restored.Items) != 3 {
+		t.Fatalf("len(restored.Items) = %d, want 3", len(restored.Items))
+	}
+
+	if got := restored.Items[0]; got.Status != StatusDone || got.Progress != 1 {
+		t.Errorf("item 0 = %+v, want done/1", got)
+	}
+	// An item left mid-download is reset to pending so Start will retry it.
+	if got := restored.Items[1]; got.Status != StatusPending {
+		t.Errorf("item 1 status = %q, want %q", got.Status, StatusPending)
+	}
+	if got := restored.Items[2]; got.Status != StatusFailed || got.Error != "boom" {
+		t.Errorf("item 2 = %+v, want failed/boom", got)
+	}
+}
+
+func TestUnmarshalEmpty(t *testing.T) {
+	q := NewQueue(1)
+	if err := q.Unmarshal(""); err != nil {
+		t.Fatalf("Unmarshal(\"\") error = %v", err)
+	}
+	if q.Items != nil {
+		t.Errorf("Items = %v, want nil after empty Unmarshal", q.Items)
+	}
+}